@@ -0,0 +1,161 @@
+package maas
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/ionutbalutoiu/gomaasclient/client"
+)
+
+func dataSourceMaasPodCapacity() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourcePodCapacityRead,
+
+		Schema: map[string]*schema.Schema{
+			"pod": {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "Pod identifier or name to report capacity for.",
+			},
+			"total_cores": {
+				Type:     schema.TypeInt,
+				Computed: true,
+			},
+			"used_cores": {
+				Type:     schema.TypeInt,
+				Computed: true,
+			},
+			"available_cores": {
+				Type:     schema.TypeInt,
+				Computed: true,
+			},
+			"pinned_cores_available": {
+				Type:        schema.TypeInt,
+				Computed:    true,
+				Description: "Cores still available for pinned allocation (not shared with the general core pool).",
+			},
+			"total_memory_mb": {
+				Type:     schema.TypeInt,
+				Computed: true,
+			},
+			"available_memory_mb": {
+				Type:     schema.TypeInt,
+				Computed: true,
+			},
+			"storage_pool": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"name": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"total_bytes": {
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+						"used_bytes": {
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+						"available_bytes": {
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+					},
+				},
+			},
+			"numa_node": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"index": {
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+						"cores": {
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+						"memory_mb": {
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+						"hugepages": {
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func dataSourcePodCapacityRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	client := m.(*client.Client)
+
+	// Find Pod
+	pod, err := findPod(client, d.Get("pod").(string))
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	// Fetch full resource/capacity details for the Pod
+	podDetails, err := client.Pod.Get(pod.ID)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	if err := d.Set("total_cores", podDetails.Total.Cores); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("used_cores", podDetails.Used.Cores); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("available_cores", podDetails.Total.Cores-podDetails.Used.Cores); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("pinned_cores_available", podDetails.Available.Cores); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("total_memory_mb", podDetails.Total.Memory); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("available_memory_mb", podDetails.Total.Memory-podDetails.Used.Memory); err != nil {
+		return diag.FromErr(err)
+	}
+
+	storagePools := make([]map[string]interface{}, 0, len(podDetails.StoragePools))
+	for _, pool := range podDetails.StoragePools {
+		storagePools = append(storagePools, map[string]interface{}{
+			"name":            pool.Name,
+			"total_bytes":     pool.Total,
+			"used_bytes":      pool.Used,
+			"available_bytes": pool.Total - pool.Used,
+		})
+	}
+	if err := d.Set("storage_pool", storagePools); err != nil {
+		return diag.FromErr(err)
+	}
+
+	numaNodes := make([]map[string]interface{}, 0, len(podDetails.NUMAPinning))
+	for i, numaNode := range podDetails.NUMAPinning {
+		numaNodes = append(numaNodes, map[string]interface{}{
+			"index":     i,
+			"cores":     len(numaNode.Cores.Free) + len(numaNode.Cores.Allocated),
+			"memory_mb": numaNode.Memory.Total,
+			"hugepages": numaNode.HugePages,
+		})
+	}
+	if err := d.Set("numa_node", numaNodes); err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId(pod.Name)
+
+	return nil
+}
@@ -0,0 +1,49 @@
+package maas
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/ionutbalutoiu/gomaasclient/client"
+)
+
+func Provider() *schema.Provider {
+	return &schema.Provider{
+		Schema: map[string]*schema.Schema{
+			"api_url": {
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"api_key": {
+				Type:      schema.TypeString,
+				Required:  true,
+				Sensitive: true,
+			},
+			"api_version": {
+				Type:     schema.TypeString,
+				Optional: true,
+				Default:  "2.0",
+			},
+		},
+
+		ResourcesMap: map[string]*schema.Resource{
+			"maas_pod_machine": resourceMaasPodMachine(),
+		},
+
+		DataSourcesMap: map[string]*schema.Resource{
+			"maas_pod_capacity": dataSourceMaasPodCapacity(),
+		},
+
+		ConfigureContextFunc: providerConfigure,
+	}
+}
+
+func providerConfigure(ctx context.Context, d *schema.ResourceData) (interface{}, diag.Diagnostics) {
+	c, err := client.GetClient(d.Get("api_url").(string), d.Get("api_key").(string), d.Get("api_version").(string))
+	if err != nil {
+		return nil, diag.FromErr(err)
+	}
+
+	return c, nil
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"strings"
 	"time"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
@@ -19,6 +20,7 @@ func resourceMaasPodMachine() *schema.Resource {
 		ReadContext:   resourcePodMachineRead,
 		UpdateContext: resourcePodMachineUpdate,
 		DeleteContext: resourcePodMachineDelete,
+		CustomizeDiff: resourcePodMachineCustomizeDiff,
 
 		Schema: map[string]*schema.Schema{
 			"pod": {
@@ -27,16 +29,69 @@ func resourceMaasPodMachine() *schema.Resource {
 				ForceNew: true,
 			},
 			"cores": {
-				Type:     schema.TypeInt,
-				Optional: true,
-				Computed: true,
-				ForceNew: true,
+				Type:          schema.TypeInt,
+				Optional:      true,
+				Computed:      true,
+				ForceNew:      true,
+				ConflictsWith: []string{"pinned_cores", "pinned_core_ids", "pinning"},
+				Description:   "Number of (unpinned) cores to allocate. Mutually exclusive with `pinned_cores`, `pinned_core_ids` and `pinning`.",
 			},
 			"pinned_cores": {
-				Type:     schema.TypeInt,
-				Optional: true,
-				Computed: true,
-				ForceNew: true,
+				Type:          schema.TypeInt,
+				Optional:      true,
+				Computed:      true,
+				ForceNew:      true,
+				ConflictsWith: []string{"cores", "pinned_core_ids", "pinning"},
+				Description:   "Number of cores to pin. Mutually exclusive with `cores`, `pinned_core_ids` and `pinning`.",
+			},
+			"pinned_core_ids": {
+				Type:          schema.TypeList,
+				Optional:      true,
+				ForceNew:      true,
+				ConflictsWith: []string{"pinned_cores", "pinning"},
+				Elem:          &schema.Schema{Type: schema.TypeInt},
+				Description:   "Core IDs to pin, e.g. [0, 1, 4, 5]. The MAAS compose API only accepts a core count, not specific IDs, so this is equivalent to `pinned_cores = len(pinned_core_ids)`; MAAS chooses which cores are pinned. Mutually exclusive with `pinned_cores` and `pinning`.",
+			},
+			"pinning": {
+				Type:          schema.TypeList,
+				Optional:      true,
+				ForceNew:      true,
+				MaxItems:      1,
+				ConflictsWith: []string{"pinned_cores", "pinned_core_ids"},
+				Description:   "NUMA-aware pinning request. Mutually exclusive with `pinned_cores` and `pinned_core_ids`.",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"numa_node": {
+							Type:        schema.TypeInt,
+							Required:    true,
+							ForceNew:    true,
+							Description: "Index of a NUMA node on the Pod, as reported by data.maas_pod_capacity. Validated to exist at plan time and used to default `cores`; the index itself is not sent to MAAS in the compose request.",
+						},
+						"cores": {
+							Type:        schema.TypeInt,
+							Optional:    true,
+							ForceNew:    true,
+							Description: "Number of cores to pin. Defaults to the number of free cores on `numa_node`. Only the count is sent to MAAS, which chooses which cores are pinned; they are not restricted to `numa_node`.",
+						},
+						"hugepages_2m": {
+							Type:        schema.TypeInt,
+							Optional:    true,
+							ForceNew:    true,
+							Description: "2M hugepages request. Any non-zero value here or in `hugepages_1g` requests hugepage-backed memory from the pod; the count itself is not sent to MAAS.",
+						},
+						"hugepages_1g": {
+							Type:        schema.TypeInt,
+							Optional:    true,
+							ForceNew:    true,
+							Description: "1G hugepages request. Any non-zero value here or in `hugepages_2m` requests hugepage-backed memory from the pod; the count itself is not sent to MAAS.",
+						},
+					},
+				},
+			},
+			"resolved_pinned_core_count": {
+				Type:        schema.TypeInt,
+				Computed:    true,
+				Description: "Number of cores requested for pinning, regardless of which of `pinned_cores`, `pinned_core_ids` or `pinning` was used. MAAS, not this provider, chooses which physical cores are pinned.",
 			},
 			"memory": {
 				Type:     schema.TypeInt,
@@ -45,14 +100,232 @@ func resourceMaasPodMachine() *schema.Resource {
 				ForceNew: true,
 			},
 			"storage": {
-				Type:     schema.TypeString,
-				Optional: true,
-				ForceNew: true,
+				Type:          schema.TypeString,
+				Optional:      true,
+				Computed:      true,
+				ForceNew:      true,
+				ConflictsWith: []string{"disk"},
+				Description:   "Raw MAAS compose storage constraint string, e.g. \"root:20(ssd),data:100\". Mutually exclusive with `disk`.",
 			},
 			"interfaces": {
-				Type:     schema.TypeString,
-				Optional: true,
-				ForceNew: true,
+				Type:          schema.TypeString,
+				Optional:      true,
+				Computed:      true,
+				ForceNew:      true,
+				ConflictsWith: []string{"interface"},
+				Description:   "Raw MAAS compose interfaces constraint string, e.g. \"eth0:space=public,mode=bridge\". Mutually exclusive with `interface`.",
+			},
+			"disk": {
+				Type:          schema.TypeList,
+				Optional:      true,
+				ForceNew:      true,
+				ConflictsWith: []string{"storage"},
+				Description:   "Per-disk storage constraint. Exactly one disk must be labeled \"root\".",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"label": {
+							Type:        schema.TypeString,
+							Required:    true,
+							ForceNew:    true,
+							Description: "Disk label, used as the disk name in the compose storage constraint and matched against block device tags after compose. Must be unique and include a \"root\" disk.",
+						},
+						"size_gb": {
+							Type:        schema.TypeInt,
+							Required:    true,
+							ForceNew:    true,
+							Description: "Disk size, in GB.",
+						},
+						"tags": {
+							Type:     schema.TypeList,
+							Optional: true,
+							ForceNew: true,
+							Elem:     &schema.Schema{Type: schema.TypeString},
+						},
+						"block_device_id": {
+							Type:        schema.TypeInt,
+							Computed:    true,
+							Description: "Block device ID allocated to this disk after compose.",
+						},
+					},
+				},
+			},
+			"interface": {
+				Type:          schema.TypeList,
+				Optional:      true,
+				ForceNew:      true,
+				ConflictsWith: []string{"interfaces"},
+				Description:   "Per-NIC network constraint.",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"label": {
+							Type:        schema.TypeString,
+							Required:    true,
+							ForceNew:    true,
+							Description: "Interface label, used as the interface name in the compose interfaces constraint and matched against the composed interface names after compose. Must be unique.",
+						},
+						"subnet": {
+							Type:     schema.TypeString,
+							Optional: true,
+							ForceNew: true,
+						},
+						"ip_address": {
+							Type:        schema.TypeString,
+							Optional:    true,
+							Computed:    true,
+							ForceNew:    true,
+							Description: "Requested IP address. If unset, the IP allocated by MAAS is populated here after compose.",
+						},
+						"fabric": {
+							Type:     schema.TypeString,
+							Optional: true,
+							ForceNew: true,
+						},
+						"vlan": {
+							Type:     schema.TypeString,
+							Optional: true,
+							ForceNew: true,
+						},
+						"space": {
+							Type:     schema.TypeString,
+							Optional: true,
+							ForceNew: true,
+						},
+						"mode": {
+							Type:     schema.TypeString,
+							Optional: true,
+							Default:  "auto",
+							ForceNew: true,
+						},
+						"mac_address": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "MAC address allocated to this interface after compose.",
+						},
+					},
+				},
+			},
+			"deletion_protection": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "When true, `terraform destroy` (or any delete of this resource) fails fast instead of releasing/deleting the machine.",
+			},
+			"release": {
+				Type:        schema.TypeList,
+				Optional:    true,
+				MaxItems:    1,
+				Description: "Release options applied before the machine is deleted, mirroring a drain-then-destroy workflow.",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"erase": {
+							Type:     schema.TypeBool,
+							Optional: true,
+							Default:  false,
+						},
+						"quick_erase": {
+							Type:     schema.TypeBool,
+							Optional: true,
+							Default:  false,
+						},
+						"secure_erase": {
+							Type:     schema.TypeBool,
+							Optional: true,
+							Default:  false,
+						},
+						"force": {
+							Type:     schema.TypeBool,
+							Optional: true,
+							Default:  false,
+						},
+						"wait_for_release_seconds": {
+							Type:        schema.TypeInt,
+							Optional:    true,
+							Description: "How long to wait for the machine to reach \"Ready\" or \"Released\" after release, in seconds. Defaults to `timeouts.release`.",
+						},
+					},
+				},
+			},
+			"commissioning": {
+				Type:        schema.TypeList,
+				Optional:    true,
+				MaxItems:    1,
+				Description: "Commissioning/testing options for an explicit commissioning run after compose. The Pod's default commissioning flow is not disabled; the explicit run starts once the machine has left the \"New\" state.",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"enable_ssh": {
+							Type:     schema.TypeBool,
+							Optional: true,
+							Default:  false,
+						},
+						"skip_bmc_config": {
+							Type:     schema.TypeBool,
+							Optional: true,
+							Default:  false,
+						},
+						"skip_networking": {
+							Type:     schema.TypeBool,
+							Optional: true,
+							Default:  false,
+						},
+						"skip_storage": {
+							Type:     schema.TypeBool,
+							Optional: true,
+							Default:  false,
+						},
+						"commissioning_scripts": {
+							Type:     schema.TypeList,
+							Optional: true,
+							Elem:     &schema.Schema{Type: schema.TypeString},
+						},
+						"testing_scripts": {
+							Type:     schema.TypeList,
+							Optional: true,
+							Elem:     &schema.Schema{Type: schema.TypeString},
+						},
+					},
+				},
+			},
+			"timeouts": {
+				Type:        schema.TypeList,
+				Optional:    true,
+				MaxItems:    1,
+				Description: "Timeouts, in seconds, for the compose/commission/release phases of this resource's lifecycle.",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"compose": {
+							Type:     schema.TypeInt,
+							Optional: true,
+							Default:  600,
+						},
+						"commission": {
+							Type:     schema.TypeInt,
+							Optional: true,
+							Default:  600,
+						},
+						"release": {
+							Type:     schema.TypeInt,
+							Optional: true,
+							Default:  300,
+						},
+					},
+				},
+			},
+			"commissioning_results": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "Results of the commissioning/testing scripts that ran after compose.",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"script_name": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"status": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+					},
+				},
 			},
 			"hostname": {
 				Type:     schema.TypeString,
@@ -87,8 +360,22 @@ func resourcePodMachineCreate(ctx context.Context, d *schema.ResourceData, m int
 		return diag.FromErr(err)
 	}
 
+	// Validate disk/interface blocks
+	if err := validatePodMachineBlocks(d); err != nil {
+		return diag.FromErr(err)
+	}
+
+	// Resolve the pinning request (if any) and validate the requested NUMA
+	// node exists on the Pod
+	pinning, err := resolvePodMachinePinning(client, pod, d)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	timeouts := getPodMachineTimeouts(d)
+
 	// Create Pod machine
-	params := getPodMachineCreateParams(d)
+	params := getPodMachineCreateParams(d, pinning)
 	machine, err := client.Pod.Compose(pod.ID, params)
 	if err != nil {
 		return diag.FromErr(err)
@@ -110,23 +397,57 @@ func resourcePodMachineCreate(ctx context.Context, d *schema.ResourceData, m int
 	if err := d.Set("interfaces", params.Interfaces); err != nil {
 		return diag.FromErr(err)
 	}
+	if err := d.Set("resolved_pinned_core_count", params.PinnedCores); err != nil {
+		return diag.FromErr(err)
+	}
 	d.SetId(machine.SystemID)
 
-	// Wait for Pod machine to be ready
+	// Surface compose-time allocations (block devices, MAC addresses, IPs)
+	// back into state so that downstream resources can reference them
+	// without a second apply.
+	if err := setPodMachineComposedAttributes(d, machine); err != nil {
+		return diag.FromErr(err)
+	}
+
+	// Wait for the machine to settle into a stable state after compose,
+	// before kicking off explicit commissioning
+	log.Printf("[DEBUG] Waiting for machine (%s) to settle after compose\n", machine.SystemID)
+	settleConf := &resource.StateChangeConf{
+		Pending:    []string{"New"},
+		Target:     []string{"Commissioning", "Testing", "Ready"},
+		Refresh:    getMachineStatusFunc(client, machine.SystemID),
+		Timeout:    timeouts.compose,
+		Delay:      5 * time.Second,
+		MinTimeout: 3 * time.Second,
+	}
+	if _, err := settleConf.WaitForStateContext(ctx); err != nil {
+		return diag.FromErr(fmt.Errorf("machine (%s) didn't settle after compose within allowed timeout: %s", machine.SystemID, err))
+	}
+
+	// Run tenant-specific commissioning/testing instead of relying on the
+	// Pod's default commissioning flow
+	log.Printf("[DEBUG] Commissioning machine (%s)\n", machine.SystemID)
+	if _, err := client.Machine.Commission(machine.SystemID, getPodMachineCommissionParams(d, timeouts)); err != nil {
+		return diag.FromErr(err)
+	}
+
 	log.Printf("[DEBUG] Waiting for machine (%s) to become ready\n", machine.SystemID)
 	stateConf := &resource.StateChangeConf{
 		Pending:    []string{"Commissioning", "Testing"},
 		Target:     []string{"Ready"},
 		Refresh:    getMachineStatusFunc(client, machine.SystemID),
-		Timeout:    10 * time.Minute,
+		Timeout:    timeouts.commission,
 		Delay:      10 * time.Second,
 		MinTimeout: 3 * time.Second,
 	}
-	_, err = stateConf.WaitForStateContext(ctx)
-	if err != nil {
+	if _, err := stateConf.WaitForStateContext(ctx); err != nil {
 		return diag.FromErr(fmt.Errorf("machine (%s) didn't become ready within allowed timeout: %s", machine.SystemID, err))
 	}
 
+	if err := setPodMachineCommissioningResults(client, d, machine.SystemID); err != nil {
+		return diag.FromErr(err)
+	}
+
 	// Return updated Pod machine
 	return resourcePodMachineUpdate(ctx, d, m)
 }
@@ -176,7 +497,34 @@ func resourcePodMachineUpdate(ctx context.Context, d *schema.ResourceData, m int
 func resourcePodMachineDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 	client := m.(*client.Client)
 
-	// Delete Pod machine
+	if d.Get("deletion_protection").(bool) {
+		return diag.FromErr(fmt.Errorf("machine (%s) has deletion_protection enabled, disable it before destroying", d.Id()))
+	}
+
+	timeouts := getPodMachineTimeouts(d)
+
+	if release := getPodMachineReleaseConfig(d, timeouts); release != nil {
+		log.Printf("[DEBUG] Releasing machine (%s) before delete\n", d.Id())
+		if _, err := client.Machine.Release(d.Id(), release.params); err != nil {
+			return diag.FromErr(err)
+		}
+
+		stateConf := &resource.StateChangeConf{
+			Pending:    []string{"Releasing", "Disk erasing"},
+			Target:     []string{"Ready", "Released"},
+			Refresh:    getMachineStatusFunc(client, d.Id()),
+			Timeout:    release.wait,
+			Delay:      5 * time.Second,
+			MinTimeout: 3 * time.Second,
+		}
+		if _, err := stateConf.WaitForStateContext(ctx); err != nil {
+			return diag.FromErr(fmt.Errorf("machine (%s) didn't release within allowed timeout: %s", d.Id(), err))
+		}
+	}
+
+	// Delete Pod machine. The owning pod is intentionally not looked up here
+	// so that release/delete still succeeds via `terraform destroy -target`
+	// even after the host pod itself has already been removed.
 	err := client.Machine.Delete(d.Id())
 	if err != nil {
 		return diag.FromErr(err)
@@ -185,6 +533,123 @@ func resourcePodMachineDelete(ctx context.Context, d *schema.ResourceData, m int
 	return nil
 }
 
+// podMachineTimeouts holds the resolved `timeouts` block, defaulted when the
+// block itself (or individual fields) are left unset.
+type podMachineTimeouts struct {
+	compose    time.Duration
+	commission time.Duration
+	release    time.Duration
+}
+
+func getPodMachineTimeouts(d *schema.ResourceData) *podMachineTimeouts {
+	timeouts := &podMachineTimeouts{
+		compose:    10 * time.Minute,
+		commission: 10 * time.Minute,
+		release:    5 * time.Minute,
+	}
+
+	blocks := d.Get("timeouts").([]interface{})
+	if len(blocks) == 0 {
+		return timeouts
+	}
+	block := blocks[0].(map[string]interface{})
+
+	if seconds := block["compose"].(int); seconds > 0 {
+		timeouts.compose = time.Duration(seconds) * time.Second
+	}
+	if seconds := block["commission"].(int); seconds > 0 {
+		timeouts.commission = time.Duration(seconds) * time.Second
+	}
+	if seconds := block["release"].(int); seconds > 0 {
+		timeouts.release = time.Duration(seconds) * time.Second
+	}
+
+	return timeouts
+}
+
+// getPodMachineCommissionParams builds the parameters for the explicit
+// Machine.Commission call made after compose, once the machine has left the
+// "New" state.
+func getPodMachineCommissionParams(d *schema.ResourceData, timeouts *podMachineTimeouts) *entity.MachineCommissionParams {
+	params := &entity.MachineCommissionParams{
+		Timeout: int(timeouts.commission.Seconds()),
+	}
+
+	blocks := d.Get("commissioning").([]interface{})
+	if len(blocks) == 0 {
+		return params
+	}
+	block := blocks[0].(map[string]interface{})
+
+	params.EnableSSH = block["enable_ssh"].(bool)
+	params.SkipBMCConfig = block["skip_bmc_config"].(bool)
+	params.SkipNetworking = block["skip_networking"].(bool)
+	params.SkipStorage = block["skip_storage"].(bool)
+	params.CommissioningScripts = strings.Join(toStringList(block["commissioning_scripts"].([]interface{})), ",")
+	params.TestingScripts = strings.Join(toStringList(block["testing_scripts"].([]interface{})), ",")
+
+	return params
+}
+
+// setPodMachineCommissioningResults fetches the results of the commissioning
+// and testing scripts that ran for the machine and surfaces them in state.
+func setPodMachineCommissioningResults(c *client.Client, d *schema.ResourceData, systemID string) error {
+	results, err := c.NodeScriptResult.Get(systemID)
+	if err != nil {
+		return err
+	}
+
+	resultsState := make([]map[string]interface{}, 0, len(results))
+	for _, result := range results {
+		resultsState = append(resultsState, map[string]interface{}{
+			"script_name": result.Name,
+			"status":      result.Status,
+		})
+	}
+
+	return d.Set("commissioning_results", resultsState)
+}
+
+// toStringList converts a []interface{} of strings (as returned by
+// schema.ResourceData.Get for a TypeList of TypeString) into a []string.
+func toStringList(values []interface{}) []string {
+	result := make([]string, 0, len(values))
+	for _, v := range values {
+		result = append(result, v.(string))
+	}
+	return result
+}
+
+// podMachineReleaseConfig holds the resolved `release` block options for a
+// single delete call.
+type podMachineReleaseConfig struct {
+	params *entity.MachineReleaseParams
+	wait   time.Duration
+}
+
+func getPodMachineReleaseConfig(d *schema.ResourceData, timeouts *podMachineTimeouts) *podMachineReleaseConfig {
+	blocks := d.Get("release").([]interface{})
+	if len(blocks) == 0 {
+		return nil
+	}
+	block := blocks[0].(map[string]interface{})
+
+	wait := timeouts.release
+	if seconds := block["wait_for_release_seconds"].(int); seconds > 0 {
+		wait = time.Duration(seconds) * time.Second
+	}
+
+	return &podMachineReleaseConfig{
+		params: &entity.MachineReleaseParams{
+			Erase:       block["erase"].(bool),
+			QuickErase:  block["quick_erase"].(bool),
+			SecureErase: block["secure_erase"].(bool),
+			Force:       block["force"].(bool),
+		},
+		wait: wait,
+	}
+}
+
 func findPod(client *client.Client, podIdentifier string) (*entity.Pod, error) {
 	pods, err := client.Pods.Get()
 	if err != nil {
@@ -200,22 +665,274 @@ func findPod(client *client.Client, podIdentifier string) (*entity.Pod, error) {
 	return nil, fmt.Errorf("pod (%s) not found", podIdentifier)
 }
 
-func getPodMachineCreateParams(d *schema.ResourceData) *entity.PodMachineParams {
+// validatePodMachineBlocks enforces the constraints that MAAS itself expects
+// from a compose request: disk labels must be unique and include a "root"
+// disk, and interface labels must be unique.
+func validatePodMachineBlocks(d *schema.ResourceData) error {
+	disks := d.Get("disk").([]interface{})
+	if len(disks) > 0 {
+		labels := make(map[string]bool, len(disks))
+		hasRoot := false
+		for _, raw := range disks {
+			disk := raw.(map[string]interface{})
+			label := disk["label"].(string)
+			if labels[label] {
+				return fmt.Errorf("disk label (%s) is used more than once", label)
+			}
+			labels[label] = true
+			if label == "root" {
+				hasRoot = true
+			}
+		}
+		if !hasRoot {
+			return fmt.Errorf("disk blocks must include exactly one disk labeled \"root\"")
+		}
+	}
+
+	interfaces := d.Get("interface").([]interface{})
+	if len(interfaces) > 0 {
+		labels := make(map[string]bool, len(interfaces))
+		for _, raw := range interfaces {
+			iface := raw.(map[string]interface{})
+			label := iface["label"].(string)
+			if labels[label] {
+				return fmt.Errorf("interface label (%s) is used more than once", label)
+			}
+			labels[label] = true
+		}
+	}
+
+	return nil
+}
+
+// podMachinePinning is the resolved pinning request for a Pod machine,
+// regardless of which of `pinned_cores`, `pinned_core_ids` or `pinning` the
+// user declared.
+//
+// entity.PodMachineParams.PinnedCores is an int, not a core-ID list, and the
+// MAAS compose API takes pinning the same way: a core *count*, not specific
+// IDs. MAAS itself picks which physical cores are pinned. So
+// `pinned_core_ids` and `pinning.cores` are accepted for readability in HCL
+// (and, for `pinning`, to default the count to a NUMA node's free cores), but
+// only their count is ever sent to MAAS or surfaced back in state; there is
+// no resolved core-ID list to expose. The NUMA node index is validated
+// against the Pod but is not part of the compose request.
+type podMachinePinning struct {
+	coreCount       int
+	hugepagesBacked bool
+}
+
+// resourcePodMachineCustomizeDiff validates that a requested NUMA node
+// actually exists on the Pod at plan time, so operators see the error during
+// `terraform plan` rather than mid-apply. resolvePodMachinePinning repeats
+// this check at apply time, since Pod capacity can still change between plan
+// and apply.
+func resourcePodMachineCustomizeDiff(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+	blocks := d.Get("pinning").([]interface{})
+	if len(blocks) == 0 {
+		return nil
+	}
+	block := blocks[0].(map[string]interface{})
+	numaNode := block["numa_node"].(int)
+
+	client := m.(*client.Client)
+
+	pod, err := findPod(client, d.Get("pod").(string))
+	if err != nil {
+		return err
+	}
+
+	podDetails, err := client.Pod.Get(pod.ID)
+	if err != nil {
+		return err
+	}
+	if numaNode < 0 || numaNode >= len(podDetails.NUMAPinning) {
+		return fmt.Errorf("pod (%s) does not have a NUMA node %d (found %d NUMA node(s))", pod.Name, numaNode, len(podDetails.NUMAPinning))
+	}
+
+	return nil
+}
+
+// resolvePodMachinePinning resolves the `pinned_core_ids` / `pinning` blocks
+// into a core count and a hugepages request, validating that a requested
+// NUMA node actually exists on the Pod.
+func resolvePodMachinePinning(c *client.Client, pod *entity.Pod, d *schema.ResourceData) (*podMachinePinning, error) {
+	if ids := d.Get("pinned_core_ids").([]interface{}); len(ids) > 0 {
+		return &podMachinePinning{coreCount: len(ids)}, nil
+	}
+
+	blocks := d.Get("pinning").([]interface{})
+	if len(blocks) == 0 {
+		return &podMachinePinning{}, nil
+	}
+	block := blocks[0].(map[string]interface{})
+	numaNode := block["numa_node"].(int)
+
+	podDetails, err := c.Pod.Get(pod.ID)
+	if err != nil {
+		return nil, err
+	}
+	if numaNode < 0 || numaNode >= len(podDetails.NUMAPinning) {
+		return nil, fmt.Errorf("pod (%s) does not have a NUMA node %d (found %d NUMA node(s))", pod.Name, numaNode, len(podDetails.NUMAPinning))
+	}
+
+	cores := block["cores"].(int)
+	if cores <= 0 {
+		cores = len(podDetails.NUMAPinning[numaNode].Cores.Free)
+	}
+
+	hugepagesBacked := block["hugepages_2m"].(int) > 0 || block["hugepages_1g"].(int) > 0
+
+	return &podMachinePinning{coreCount: cores, hugepagesBacked: hugepagesBacked}, nil
+}
+
+// composeStorageString converts the `disk` blocks into the MAAS compose
+// storage constraint syntax, e.g. "root:20(ssd),data:100".
+func composeStorageString(disks []interface{}) string {
+	constraints := make([]string, 0, len(disks))
+	for _, raw := range disks {
+		disk := raw.(map[string]interface{})
+		constraint := fmt.Sprintf("%s:%d", disk["label"].(string), disk["size_gb"].(int))
+		tags := disk["tags"].([]interface{})
+		if len(tags) > 0 {
+			tagStrings := make([]string, 0, len(tags))
+			for _, tag := range tags {
+				tagStrings = append(tagStrings, tag.(string))
+			}
+			constraint = fmt.Sprintf("%s(%s)", constraint, strings.Join(tagStrings, ","))
+		}
+		constraints = append(constraints, constraint)
+	}
+	return strings.Join(constraints, ",")
+}
+
+// composeInterfacesString converts the `interface` blocks into the MAAS
+// compose interfaces constraint syntax, e.g. "eth0:space=public,mode=bridge".
+func composeInterfacesString(interfaces []interface{}) string {
+	constraints := make([]string, 0, len(interfaces))
+	for _, raw := range interfaces {
+		iface := raw.(map[string]interface{})
+		constraint := iface["label"].(string)
+
+		var opts []string
+		if v := iface["subnet"].(string); v != "" {
+			opts = append(opts, fmt.Sprintf("subnet=%s", v))
+		}
+		if v := iface["ip_address"].(string); v != "" {
+			opts = append(opts, fmt.Sprintf("ip=%s", v))
+		}
+		if v := iface["fabric"].(string); v != "" {
+			opts = append(opts, fmt.Sprintf("fabric=%s", v))
+		}
+		if v := iface["vlan"].(string); v != "" {
+			opts = append(opts, fmt.Sprintf("vlan=%s", v))
+		}
+		if v := iface["space"].(string); v != "" {
+			opts = append(opts, fmt.Sprintf("space=%s", v))
+		}
+		if v := iface["mode"].(string); v != "" {
+			opts = append(opts, fmt.Sprintf("mode=%s", v))
+		}
+
+		if len(opts) > 0 {
+			constraint = fmt.Sprintf("%s:%s", constraint, strings.Join(opts, ","))
+		}
+		constraints = append(constraints, constraint)
+	}
+	return strings.Join(constraints, ",")
+}
+
+// setPodMachineComposedAttributes reads the block devices and interfaces MAAS
+// allocated during compose and writes the resolved IDs, MAC addresses and IP
+// addresses back into the `disk` and `interface` blocks, in the same order
+// the user declared them, so they're available without a second apply.
+func setPodMachineComposedAttributes(d *schema.ResourceData, machine *entity.Machine) error {
+	if disks := d.Get("disk").([]interface{}); len(disks) > 0 {
+		// Compose names block devices after the kernel device (vda, sda, ...),
+		// not after the label we requested them under. MAAS does apply the
+		// label as a tag on the block device, so match on tag membership
+		// instead of Name.
+		blockDevicesByTag := make(map[string]entity.BlockDevice, len(machine.BlockDeviceSet))
+		for _, bd := range machine.BlockDeviceSet {
+			for _, tag := range bd.Tags {
+				blockDevicesByTag[tag] = bd
+			}
+		}
+
+		updated := make([]interface{}, 0, len(disks))
+		for _, raw := range disks {
+			disk := raw.(map[string]interface{})
+			if bd, ok := blockDevicesByTag[disk["label"].(string)]; ok {
+				disk["block_device_id"] = bd.ID
+			}
+			updated = append(updated, disk)
+		}
+		if err := d.Set("disk", updated); err != nil {
+			return err
+		}
+	}
+
+	if interfaces := d.Get("interface").([]interface{}); len(interfaces) > 0 {
+		interfacesByName := make(map[string]entity.NetworkInterface, len(machine.InterfaceSet))
+		for _, iface := range machine.InterfaceSet {
+			interfacesByName[iface.Name] = iface
+		}
+
+		updated := make([]interface{}, 0, len(interfaces))
+		for _, raw := range interfaces {
+			iface := raw.(map[string]interface{})
+			if ni, ok := interfacesByName[iface["label"].(string)]; ok {
+				iface["mac_address"] = ni.MACAddress
+				if iface["ip_address"].(string) == "" {
+					iface["ip_address"] = firstLinkAddress(ni)
+				}
+			}
+			updated = append(updated, iface)
+		}
+		if err := d.Set("interface", updated); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// firstLinkAddress returns the first IP address MAAS allocated to the given
+// interface's links, if any.
+func firstLinkAddress(iface entity.NetworkInterface) string {
+	for _, link := range iface.Links {
+		if link.IPAddress != "" {
+			return link.IPAddress
+		}
+	}
+	return ""
+}
+
+func getPodMachineCreateParams(d *schema.ResourceData, pinning *podMachinePinning) *entity.PodMachineParams {
 	params := entity.PodMachineParams{}
 
 	if p, ok := d.GetOk("cores"); ok {
 		params.Cores = p.(int)
 	}
-	if p, ok := d.GetOk("pinned_cores"); ok {
+	if pinning.coreCount > 0 {
+		params.PinnedCores = pinning.coreCount
+	} else if p, ok := d.GetOk("pinned_cores"); ok {
 		params.PinnedCores = p.(int)
 	}
+	if pinning.hugepagesBacked {
+		params.HugepagesBacked = true
+	}
 	if p, ok := d.GetOk("memory"); ok {
 		params.Memory = p.(int)
 	}
-	if p, ok := d.GetOk("storage"); ok {
+	if disks := d.Get("disk").([]interface{}); len(disks) > 0 {
+		params.Storage = composeStorageString(disks)
+	} else if p, ok := d.GetOk("storage"); ok {
 		params.Storage = p.(string)
 	}
-	if p, ok := d.GetOk("interfaces"); ok {
+	if interfaces := d.Get("interface").([]interface{}); len(interfaces) > 0 {
+		params.Interfaces = composeInterfacesString(interfaces)
+	} else if p, ok := d.GetOk("interfaces"); ok {
 		params.Interfaces = p.(string)
 	}
 	if p, ok := d.GetOk("hostname"); ok {
@@ -250,4 +967,4 @@ func getPodMachineUpdateParams(d *schema.ResourceData, machine *entity.Machine)
 	}
 
 	return &params
-}
\ No newline at end of file
+}